@@ -0,0 +1,34 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// GetDindImageName returns the docker-in-docker image used for new instances
+// unless the caller picks one explicitly, defaulting to franela/dind and
+// overridable via the DIND_IMAGE environment variable for local dev/test.
+func GetDindImageName() string {
+	if v := os.Getenv("DIND_IMAGE"); v != "" {
+		return v
+	}
+	return "franela/dind"
+}
+
+// GetInstanceIdleTimeout returns how long an instance can go without
+// terminal/exec activity before the idle reaper evicts it, via the
+// INSTANCE_IDLE_TIMEOUT environment variable (e.g. "2h"). Zero, the default,
+// disables eviction so public deployments opt into it explicitly.
+func GetInstanceIdleTimeout() time.Duration {
+	v := os.Getenv("INSTANCE_IDLE_TIMEOUT")
+	if v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Println("invalid INSTANCE_IDLE_TIMEOUT, ignoring:", err)
+		return 0
+	}
+	return d
+}
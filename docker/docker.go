@@ -0,0 +1,89 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"net"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// CreateContainerOpts carries everything needed to create a new instance
+// container.
+type CreateContainerOpts struct {
+	Image         string
+	SessionId     string
+	PwdIpAddress  string
+	ContainerName string
+	Hostname      string
+	ServerCert    []byte
+	ServerKey     []byte
+	CACert        []byte
+	Privileged    bool
+	HostFQDN      string
+
+	// PasswdFile and GroupFile, when set, are host paths bind mounted
+	// read-only into the container as /etc/passwd and /etc/group.
+	PasswdFile string
+	GroupFile  string
+}
+
+// ContainerLogsOptions controls what slice of a container's log stream
+// ContainerLogs returns.
+type ContainerLogsOptions struct {
+	ShowStdout bool
+	ShowStderr bool
+	Follow     bool
+	Tail       string
+	Since      time.Time
+	Until      time.Time
+	Timestamps bool
+}
+
+// ExecCreateOpts configures a command created with ExecCreate.
+type ExecCreateOpts struct {
+	Cmd          []string
+	Tty          bool
+	AttachStdin  bool
+	AttachStdout bool
+	AttachStderr bool
+	Env          []string
+	WorkingDir   string
+	User         string
+}
+
+// DockerApi wraps the subset of the Docker Engine API that pwd drives
+// instances through.
+type DockerApi interface {
+	ContainerResize(name string, rows, cols uint) error
+	CreateAttachConnection(name string) (net.Conn, error)
+	CopyToContainer(name, dest, fileName string, reader io.Reader) error
+	ExecAttach(name string, cmd []string, out io.Writer) (int, error)
+	DeleteContainer(name string) error
+	CreateContainer(opts CreateContainerOpts) (string, error)
+	Exec(name string, cmd []string) (int, error)
+
+	ContainerLogs(ctx context.Context, name string, opts ContainerLogsOptions) (io.ReadCloser, error)
+
+	// ContainerStats returns the raw, newline-delimited-JSON stats stream for
+	// name. When stream is false the response body holds exactly one sample.
+	ContainerStats(ctx context.Context, name string, stream bool) (io.ReadCloser, error)
+
+	// ExecCreate registers a new command to run inside name and returns its
+	// exec id, ready to be started with ExecStart.
+	ExecCreate(name string, opts ExecCreateOpts) (string, error)
+	// ExecStart runs a command created with ExecCreate and returns the
+	// hijacked connection used to stream stdin/stdout/stderr.
+	ExecStart(execId string, tty bool) (io.ReadWriteCloser, error)
+	// ExecResize changes the TTY size of a running exec session.
+	ExecResize(execId string, rows, cols uint) error
+	// ExecInspectExitCode blocks until execId has exited and returns its exit
+	// code.
+	ExecInspectExitCode(execId string) (int, error)
+
+	// Events streams daemon events matching filters until ctx is cancelled.
+	// The error channel receives at most one error, when the stream ends.
+	Events(ctx context.Context, filterArgs filters.Args) (<-chan events.Message, <-chan error)
+}
@@ -0,0 +1,23 @@
+package event
+
+// EventType identifies the kind of event being emitted on the pwd event bus.
+type EventType string
+
+const (
+	INSTANCE_NEW          EventType = "instance new"
+	INSTANCE_DELETE       EventType = "instance delete"
+	INSTANCE_TERMINAL_OUT EventType = "instance terminal out"
+	INSTANCE_LOG          EventType = "instance log"
+	INSTANCE_STATS        EventType = "instance stats"
+	INSTANCE_DIED         EventType = "instance died"
+	INSTANCE_OOM          EventType = "instance oom"
+	INSTANCE_HEALTH       EventType = "instance health"
+	INSTANCE_NETWORK      EventType = "instance network"
+	INSTANCE_IDLE_EVICT   EventType = "instance idle evict"
+)
+
+// EventApi is the pub/sub bus sessions and instances use to notify
+// subscribers, mainly the websocket layer, of state changes.
+type EventApi interface {
+	Emit(eventType EventType, args ...interface{})
+}
@@ -0,0 +1,85 @@
+package pwd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	dockerEvents "github.com/docker/docker/api/types/events"
+	dockerFilters "github.com/docker/docker/api/types/filters"
+	"github.com/play-with-docker/play-with-docker/event"
+)
+
+// startDockerEventBridge subscribes to the Docker daemon's event stream and
+// re-emits container lifecycle events belonging to a PWD instance onto the
+// pwd event bus, so the frontend learns about crashes and health changes
+// without polling. It's started once from NewPWD and reconnects with
+// exponential backoff if the daemon connection drops.
+func (p *pwd) startDockerEventBridge() {
+	go func() {
+		backoff := time.Second
+		for {
+			if err := p.consumeDockerEvents(); err != nil {
+				log.Println("docker event bridge disconnected:", err)
+			}
+
+			time.Sleep(backoff)
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+		}
+	}()
+}
+
+func (p *pwd) consumeDockerEvents() error {
+	f := dockerFilters.NewArgs()
+	f.Add("type", "container")
+	f.Add("type", "network")
+
+	events, errs := p.docker.Events(context.Background(), f)
+	for {
+		select {
+		case err := <-errs:
+			return err
+		case e, ok := <-events:
+			if !ok {
+				return fmt.Errorf("docker event stream closed")
+			}
+			p.handleDockerEvent(e)
+		}
+	}
+}
+
+func (p *pwd) handleDockerEvent(e dockerEvents.Message) {
+	name := strings.TrimPrefix(e.Actor.Attributes["name"], "/")
+
+	instance, err := p.storage.InstanceFindByName(name)
+	if err != nil || instance == nil {
+		// Not a container PWD knows about (e.g. it belongs to another app, or
+		// it's a DinD workload running inside an instance).
+		return
+	}
+
+	switch e.Action {
+	case "die":
+		p.event.Emit(event.INSTANCE_DIED, instance.SessionId, instance.Name, e.Actor.Attributes["exitCode"])
+		p.terms.Delete(instance.SessionId, instance.Name)
+		p.stopInstanceStatsCollector(instance)
+		p.idle.forget(instance.SessionId, instance.Name)
+		if err := p.storage.InstanceSetState(instance.SessionId, instance.Name, "died"); err != nil {
+			log.Println("could not mark instance as died:", err)
+		}
+	case "oom":
+		p.event.Emit(event.INSTANCE_OOM, instance.SessionId, instance.Name)
+	case "connect", "disconnect":
+		if e.Type == "network" {
+			p.event.Emit(event.INSTANCE_NETWORK, instance.SessionId, instance.Name, e.Action)
+		}
+	default:
+		if strings.HasPrefix(e.Action, "health_status") {
+			p.event.Emit(event.INSTANCE_HEALTH, instance.SessionId, instance.Name, e.Action)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package pwd
+
+import (
+	"io"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/play-with-docker/play-with-docker/docker"
+	"github.com/play-with-docker/play-with-docker/pwd/types"
+)
+
+// ExecOptions configures a streaming exec session started with
+// InstanceExecStream.
+type ExecOptions struct {
+	Tty          bool
+	AttachStdin  bool
+	AttachStdout bool
+	AttachStderr bool
+	Env          []string
+	WorkingDir   string
+	User         string
+}
+
+// ExecSession is a live, bidirectional handle onto a command running inside
+// an instance. Stdout/Stderr are demultiplexed unless the session is a TTY, in
+// which case Docker returns a single combined stream on Stdout and Stderr is nil.
+type ExecSession struct {
+	Stdin  io.WriteCloser
+	Stdout io.Reader
+	Stderr io.Reader
+
+	execId string
+	conn   io.Closer
+	docker docker.DockerApi
+}
+
+// Resize changes the TTY size of a session started with Tty: true.
+func (s *ExecSession) Resize(rows, cols uint) error {
+	return s.docker.ExecResize(s.execId, rows, cols)
+}
+
+// Wait blocks until the command exits and returns its exit code.
+func (s *ExecSession) Wait() (int, error) {
+	return s.docker.ExecInspectExitCode(s.execId)
+}
+
+// Close releases the underlying connection. Safe to call after Wait.
+func (s *ExecSession) Close() error {
+	return s.conn.Close()
+}
+
+// InstanceExecStream runs cmd inside instance and returns a session that can
+// be used to write to stdin, read stdout/stderr as they're produced, resize a
+// TTY, and wait for the exit code. Use InstanceExec instead for simple
+// one-shot commands that only need the exit code.
+func (p *pwd) InstanceExecStream(instance *types.Instance, cmd []string, opts ExecOptions) (*ExecSession, error) {
+	defer observeAction("InstanceExecStream", time.Now())
+	p.idle.touch(instance.SessionId, instance.Name)
+
+	execId, err := p.docker.ExecCreate(instance.Name, docker.ExecCreateOpts{
+		Cmd:          cmd,
+		Tty:          opts.Tty,
+		AttachStdin:  opts.AttachStdin,
+		AttachStdout: opts.AttachStdout,
+		AttachStderr: opts.AttachStderr,
+		Env:          opts.Env,
+		WorkingDir:   opts.WorkingDir,
+		User:         opts.User,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := p.docker.ExecStart(execId, opts.Tty)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &ExecSession{
+		Stdin:  conn,
+		execId: execId,
+		conn:   conn,
+		docker: p.docker,
+	}
+
+	if opts.Tty {
+		session.Stdout = conn
+		return session, nil
+	}
+
+	outR, outW := io.Pipe()
+	errR, errW := io.Pipe()
+	go func() {
+		if _, err := stdcopy.StdCopy(outW, errW, conn); err != nil && err != io.EOF {
+			outW.CloseWithError(err)
+			errW.CloseWithError(err)
+			return
+		}
+		outW.Close()
+		errW.Close()
+	}()
+	session.Stdout = outR
+	session.Stderr = errR
+
+	return session, nil
+}
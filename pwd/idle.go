@@ -0,0 +1,112 @@
+package pwd
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/play-with-docker/play-with-docker/event"
+	"github.com/play-with-docker/play-with-docker/pwd/types"
+)
+
+// idleTracker records the last time each instance saw terminal or exec
+// activity, so startIdleReaper can evict instances nobody is using anymore. A
+// timeout of zero disables eviction entirely.
+type idleTracker struct {
+	mu      sync.Mutex
+	last    map[string]time.Time
+	timeout time.Duration
+}
+
+func newIdleTracker(timeout time.Duration) *idleTracker {
+	return &idleTracker{last: make(map[string]time.Time), timeout: timeout}
+}
+
+// touch records activity for an instance, keyed by "sessionId/instanceName".
+func (t *idleTracker) touch(sessionId, instanceName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.last[sessionId+"/"+instanceName] = time.Now()
+}
+
+// forget removes an instance from the tracker, e.g. once it has been deleted.
+func (t *idleTracker) forget(sessionId, instanceName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.last, sessionId+"/"+instanceName)
+}
+
+// idleSince returns how long an instance has gone without recorded activity,
+// and whether it has ever had any.
+func (t *idleTracker) idleSince(sessionId, instanceName string) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	last, ok := t.last[sessionId+"/"+instanceName]
+	if !ok {
+		return 0, false
+	}
+	return time.Since(last), true
+}
+
+// startIdleReaper starts the background goroutine that evicts instances that
+// have exceeded the configured idle timeout. It's a no-op when the timeout is
+// zero, which is how public-facing config.GetInstanceIdleTimeout disables it.
+func (p *pwd) startIdleReaper() {
+	if p.idle.timeout <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			p.reapIdleInstances()
+		}
+	}()
+}
+
+func (p *pwd) reapIdleInstances() {
+	for _, session := range p.storage.SessionGetAll() {
+		// Snapshot the instances under lock rather than ranging over
+		// session.Instances directly: InstanceNew/InstanceDelete mutate that
+		// map from other goroutines and don't take the lock themselves on
+		// the read side, so an unguarded range here can race with them.
+		session.Lock()
+		instances := make([]*types.Instance, 0, len(session.Instances))
+		for _, instance := range session.Instances {
+			instances = append(instances, instance)
+		}
+		session.Unlock()
+
+		for _, instance := range instances {
+			idle, seen := p.idle.idleSince(session.Id, instance.Name)
+			if !seen {
+				// Never touched since creation.
+				idle = time.Since(instance.CreatedAt)
+			}
+			timeout := idleTimeoutFor(seen, p.idle.timeout)
+			if idle < timeout {
+				continue
+			}
+
+			log.Printf("evicting idle instance %s (idle for %s)\n", instance.Name, idle)
+			if err := p.InstanceDelete(session, instance); err != nil {
+				log.Println("could not evict idle instance:", err)
+				continue
+			}
+			p.idle.forget(session.Id, instance.Name)
+			p.event.Emit(event.INSTANCE_IDLE_EVICT, session.Id, instance.Name)
+		}
+	}
+}
+
+// idleTimeoutFor returns the idle timeout that applies to an instance: the
+// full configured timeout once it has seen activity, or a third of it when
+// it never has, so instances abandoned right after creation don't linger as
+// long as ones that were used and then dropped.
+func idleTimeoutFor(seen bool, timeout time.Duration) time.Duration {
+	if !seen {
+		return timeout / 3
+	}
+	return timeout
+}
@@ -0,0 +1,47 @@
+package pwd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdleTimeoutForSeenUsesFullTimeout(t *testing.T) {
+	timeout := 90 * time.Minute
+
+	got := idleTimeoutFor(true, timeout)
+	if got != timeout {
+		t.Errorf("idleTimeoutFor(seen=true) = %v, want %v", got, timeout)
+	}
+}
+
+func TestIdleTimeoutForNeverSeenUsesAThird(t *testing.T) {
+	timeout := 90 * time.Minute
+
+	got := idleTimeoutFor(false, timeout)
+	want := 30 * time.Minute
+	if got != want {
+		t.Errorf("idleTimeoutFor(seen=false) = %v, want %v", got, want)
+	}
+}
+
+func TestIdleTrackerTouchForgetIdleSince(t *testing.T) {
+	tr := newIdleTracker(time.Hour)
+
+	if _, seen := tr.idleSince("session", "instance"); seen {
+		t.Fatal("idleSince reported activity for an instance that was never touched")
+	}
+
+	tr.touch("session", "instance")
+	idle, seen := tr.idleSince("session", "instance")
+	if !seen {
+		t.Fatal("idleSince did not report activity right after touch")
+	}
+	if idle < 0 || idle > time.Second {
+		t.Errorf("idleSince = %v, want close to 0 right after touch", idle)
+	}
+
+	tr.forget("session", "instance")
+	if _, seen := tr.idleSince("session", "instance"); seen {
+		t.Fatal("idleSince still reported activity after forget")
+	}
+}
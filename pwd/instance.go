@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"net"
 	"net/http"
 	"path/filepath"
 	"strings"
@@ -25,8 +24,6 @@ type sessionWriter struct {
 	event        event.EventApi
 }
 
-var terms = make(map[string]map[string]net.Conn)
-
 func (s *sessionWriter) Write(p []byte) (n int, err error) {
 	s.event.Emit(event.INSTANCE_TERMINAL_OUT, s.sessionId, s.instanceName, string(p))
 	return len(p), nil
@@ -42,6 +39,12 @@ type InstanceConfig struct {
 	Cert       []byte
 	Key        []byte
 	Host       string
+
+	// ExtraUsers and ExtraGroups are baked into a generated /etc/passwd and
+	// /etc/group that get bind mounted into the instance, so lab scenarios
+	// can pre-seed non-root users without a custom image per exercise.
+	ExtraUsers  []UserEntry
+	ExtraGroups []GroupEntry
 }
 
 func (p *pwd) InstanceResizeTerminal(instance *types.Instance, rows, cols uint) error {
@@ -51,7 +54,7 @@ func (p *pwd) InstanceResizeTerminal(instance *types.Instance, rows, cols uint)
 
 func (p *pwd) InstanceAttachTerminal(instance *types.Instance) error {
 	// already have a connection for this instance
-	if getInstanceTermConn(instance.SessionId, instance.Name) != nil {
+	if p.terms.Get(instance.SessionId, instance.Name) != nil {
 		return nil
 	}
 	conn, err := p.docker.CreateAttachConnection(instance.Name)
@@ -62,11 +65,7 @@ func (p *pwd) InstanceAttachTerminal(instance *types.Instance) error {
 
 	encoder := encoding.Replacement.NewEncoder()
 	sw := &sessionWriter{sessionId: instance.Session.Id, instanceName: instance.Name, event: p.event}
-	if terms[instance.SessionId] == nil {
-		terms[instance.SessionId] = map[string]net.Conn{instance.Name: conn}
-	} else {
-		terms[instance.SessionId][instance.Name] = conn
-	}
+	p.terms.Set(instance.SessionId, instance.Name, conn)
 	io.Copy(encoder.Writer(sw), conn)
 
 	return nil
@@ -167,10 +166,10 @@ func (p *pwd) InstanceFindByAlias(sessionPrefix, alias string) *types.Instance {
 
 func (p *pwd) InstanceDelete(session *types.Session, instance *types.Instance) error {
 	defer observeAction("InstanceDelete", time.Now())
-	conn := getInstanceTermConn(session.Id, instance.Name)
-	if conn != nil {
-		conn.Close()
-	}
+	p.terms.Delete(session.Id, instance.Name)
+	p.stopInstanceStatsCollector(instance)
+	p.idle.forget(session.Id, instance.Name)
+	removeUserFiles(session.Id, instance.Name)
 	err := p.docker.DeleteContainer(instance.Name)
 	if err != nil && !strings.Contains(err.Error(), "No such container") {
 		log.Println(err)
@@ -243,6 +242,15 @@ func (p *pwd) InstanceNew(session *types.Session, conf InstanceConfig) (*types.I
 		}
 	}
 
+	if len(conf.ExtraUsers) > 0 || len(conf.ExtraGroups) > 0 {
+		passwdFile, groupFile, err := writePasswdAndGroup(session.Id, containerName, conf.ExtraUsers, conf.ExtraGroups)
+		if err != nil {
+			return nil, err
+		}
+		opts.PasswdFile = passwdFile
+		opts.GroupFile = groupFile
+	}
+
 	ip, err := p.docker.CreateContainer(opts)
 	if err != nil {
 		return nil, err
@@ -263,6 +271,7 @@ func (p *pwd) InstanceNew(session *types.Session, conf InstanceConfig) (*types.I
 	instance.Session = session
 	// For now this condition holds through. In the future we might need a more complex logic.
 	instance.IsDockerHost = opts.Privileged
+	instance.CreatedAt = time.Now()
 
 	if session.Instances == nil {
 		session.Instances = make(map[string]*types.Instance)
@@ -270,6 +279,7 @@ func (p *pwd) InstanceNew(session *types.Session, conf InstanceConfig) (*types.I
 	session.Instances[instance.Name] = instance
 
 	go p.InstanceAttachTerminal(instance)
+	p.startInstanceStatsCollector(instance)
 
 	err = p.storage.InstanceCreate(session.Id, instance)
 	if err != nil {
@@ -285,8 +295,9 @@ func (p *pwd) InstanceNew(session *types.Session, conf InstanceConfig) (*types.I
 
 func (p *pwd) InstanceWriteToTerminal(sessionId, instanceName string, data string) {
 	defer observeAction("InstanceWriteToTerminal", time.Now())
-	conn := getInstanceTermConn(sessionId, instanceName)
+	conn := p.terms.Get(sessionId, instanceName)
 	if conn != nil && len(data) > 0 {
+		p.idle.touch(sessionId, instanceName)
 		conn.Write([]byte(data))
 	}
 }
@@ -304,9 +315,6 @@ func (p *pwd) InstanceAllowedImages() []string {
 
 func (p *pwd) InstanceExec(instance *types.Instance, cmd []string) (int, error) {
 	defer observeAction("InstanceExec", time.Now())
+	p.idle.touch(instance.SessionId, instance.Name)
 	return p.docker.Exec(instance.Name, cmd)
 }
-
-func getInstanceTermConn(sessionId, instanceName string) net.Conn {
-	return terms[sessionId][instanceName]
-}
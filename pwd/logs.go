@@ -0,0 +1,127 @@
+package pwd
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/play-with-docker/play-with-docker/docker"
+	"github.com/play-with-docker/play-with-docker/event"
+	"github.com/play-with-docker/play-with-docker/pwd/types"
+)
+
+// LogOptions controls what slice of a container's log stream InstanceLogs
+// returns.
+type LogOptions struct {
+	Follow     bool
+	Tail       string
+	Since      time.Time
+	Until      time.Time
+	Timestamps bool
+	Stdout     bool
+	Stderr     bool
+}
+
+// logWriter forwards every chunk it receives both to an underlying writer and
+// to the event bus, so subscribers see log output as it arrives rather than
+// having to poll InstanceLogs again.
+type logWriter struct {
+	sessionId    string
+	instanceName string
+	stream       string
+	event        event.EventApi
+	out          io.Writer
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.event.Emit(event.INSTANCE_LOG, w.sessionId, w.instanceName, w.stream, string(p))
+	return w.out.Write(p)
+}
+
+func (o LogOptions) toDockerOptions() docker.ContainerLogsOptions {
+	return docker.ContainerLogsOptions{
+		ShowStdout: o.Stdout,
+		ShowStderr: o.Stderr,
+		Follow:     o.Follow,
+		Tail:       o.Tail,
+		Since:      o.Since,
+		Until:      o.Until,
+		Timestamps: o.Timestamps,
+	}
+}
+
+// InstanceLogs streams an instance's container logs, demultiplexing stdout and
+// stderr into a single reader. Cancel ctx to stop a Follow stream early, e.g.
+// when the subscribing session ends.
+func (p *pwd) InstanceLogs(ctx context.Context, instance *types.Instance, opts LogOptions) (io.ReadCloser, error) {
+	defer observeAction("InstanceLogs", time.Now())
+
+	raw, err := p.docker.ContainerLogs(ctx, instance.Name, opts.toDockerOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	stdout := &logWriter{sessionId: instance.SessionId, instanceName: instance.Name, stream: "stdout", event: p.event, out: pw}
+	stderr := &logWriter{sessionId: instance.SessionId, instanceName: instance.Name, stream: "stderr", event: p.event, out: pw}
+
+	done := make(chan struct{})
+	go func() {
+		defer raw.Close()
+		defer close(done)
+		if _, err := stdcopy.StdCopy(stdout, stderr, raw); err != nil && err != io.EOF {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.Close()
+	}()
+	go func() {
+		select {
+		case <-ctx.Done():
+			raw.Close()
+		case <-done:
+		}
+	}()
+
+	return pr, nil
+}
+
+// InstanceLogsSplit behaves like InstanceLogs but keeps stdout and stderr on
+// separate readers instead of interleaving them, for callers that need to
+// tell the two apart (e.g. a terminal that colors stderr differently).
+func (p *pwd) InstanceLogsSplit(ctx context.Context, instance *types.Instance, opts LogOptions) (stdout io.ReadCloser, stderr io.ReadCloser, err error) {
+	defer observeAction("InstanceLogsSplit", time.Now())
+
+	raw, err := p.docker.ContainerLogs(ctx, instance.Name, opts.toDockerOptions())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outR, outW := io.Pipe()
+	errR, errW := io.Pipe()
+	stdoutWriter := &logWriter{sessionId: instance.SessionId, instanceName: instance.Name, stream: "stdout", event: p.event, out: outW}
+	stderrWriter := &logWriter{sessionId: instance.SessionId, instanceName: instance.Name, stream: "stderr", event: p.event, out: errW}
+
+	done := make(chan struct{})
+	go func() {
+		defer raw.Close()
+		defer close(done)
+		if _, err := stdcopy.StdCopy(stdoutWriter, stderrWriter, raw); err != nil && err != io.EOF {
+			outW.CloseWithError(err)
+			errW.CloseWithError(err)
+			return
+		}
+		outW.Close()
+		errW.Close()
+	}()
+	go func() {
+		select {
+		case <-ctx.Done():
+			raw.Close()
+		case <-done:
+		}
+	}()
+
+	return outR, errR, nil
+}
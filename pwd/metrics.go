@@ -0,0 +1,41 @@
+package pwd
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	actionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "pwd",
+		Name:      "action_duration_seconds",
+		Help:      "Duration of pwd actions.",
+	}, []string{"action"})
+
+	gaugeInstancesTotal = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "pwd",
+		Name:      "instances_total",
+		Help:      "Total number of running instances, across all sessions.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(actionDuration, gaugeInstancesTotal)
+}
+
+// observeAction records how long a pwd action took in the
+// action_duration_seconds histogram.
+func observeAction(action string, start time.Time) {
+	actionDuration.WithLabelValues(action).Observe(time.Since(start).Seconds())
+}
+
+// setGauges refreshes the aggregate, non-per-instance gauges exposed to
+// operators.
+func (p *pwd) setGauges() {
+	total := 0
+	for _, session := range p.storage.SessionGetAll() {
+		total += len(session.Instances)
+	}
+	gaugeInstancesTotal.Set(float64(total))
+}
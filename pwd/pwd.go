@@ -0,0 +1,55 @@
+package pwd
+
+import (
+	"github.com/play-with-docker/play-with-docker/config"
+	"github.com/play-with-docker/play-with-docker/docker"
+	"github.com/play-with-docker/play-with-docker/event"
+	"github.com/play-with-docker/play-with-docker/pwd/types"
+	"github.com/play-with-docker/play-with-docker/storage"
+)
+
+// pwd is the concrete implementation backing every exported PWD operation:
+// creating/inspecting/deleting instances, streaming their terminals, and
+// everything built on top of those in this package.
+type pwd struct {
+	docker  docker.DockerApi
+	event   event.EventApi
+	storage storage.StorageApi
+
+	statsCollectors *statsCollectorRegistry
+	terms           *terminalRegistry
+	idle            *idleTracker
+}
+
+// NewPWD wires up a pwd instance backed by the given Docker client, event bus
+// and storage backend.
+func NewPWD(d docker.DockerApi, e event.EventApi, s storage.StorageApi) *pwd {
+	p := &pwd{
+		docker:  d,
+		event:   e,
+		storage: s,
+
+		statsCollectors: newStatsCollectorRegistry(),
+		terms:           newTerminalRegistry(),
+		idle:            newIdleTracker(config.GetInstanceIdleTimeout()),
+	}
+
+	p.startDockerEventBridge()
+	p.startIdleReaper()
+
+	return p
+}
+
+// SessionClose releases everything a session was holding onto once it ends:
+// terminal connections, background collectors, and any generated host files.
+func (p *pwd) SessionClose(session *types.Session) error {
+	p.terms.DeleteSession(session.Id)
+	session.Lock()
+	for _, instance := range session.Instances {
+		p.stopInstanceStatsCollector(instance)
+	}
+	session.Unlock()
+	removeSessionUserFiles(session.Id)
+
+	return nil
+}
@@ -0,0 +1,233 @@
+package pwd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	dockerTypes "github.com/docker/docker/api/types"
+	"github.com/play-with-docker/play-with-docker/event"
+	"github.com/play-with-docker/play-with-docker/pwd/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// statsCollectorRegistry tracks the cancel func of the background goroutine
+// started by startInstanceStatsCollector for each running instance, keyed by
+// "sessionId/instanceName", so it can be stopped on InstanceDelete. It lives
+// on the pwd struct rather than at package scope so independent pwd instances
+// (e.g. in tests) don't share collectors.
+type statsCollectorRegistry struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+func newStatsCollectorRegistry() *statsCollectorRegistry {
+	return &statsCollectorRegistry{cancels: make(map[string]context.CancelFunc)}
+}
+
+// startInstanceStatsCollector starts a goroutine that streams stats for
+// instance and drains them, keeping the Prometheus gauges and INSTANCE_STATS
+// events flowing without a caller having to hold the channel open. It is
+// idempotent: calling it twice for the same instance is a no-op.
+func (p *pwd) startInstanceStatsCollector(instance *types.Instance) {
+	key := instance.SessionId + "/" + instance.Name
+
+	p.statsCollectors.mu.Lock()
+	if _, exists := p.statsCollectors.cancels[key]; exists {
+		p.statsCollectors.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.statsCollectors.cancels[key] = cancel
+	p.statsCollectors.mu.Unlock()
+
+	go func() {
+		stats, err := p.InstanceStats(ctx, instance)
+		if err != nil {
+			log.Println("could not start stats collector for", instance.Name, err)
+			return
+		}
+		for range stats {
+		}
+	}()
+}
+
+// stopInstanceStatsCollector stops the background collector for instance, if
+// one is running, and releases its gauges.
+func (p *pwd) stopInstanceStatsCollector(instance *types.Instance) {
+	key := instance.SessionId + "/" + instance.Name
+
+	p.statsCollectors.mu.Lock()
+	cancel, exists := p.statsCollectors.cancels[key]
+	delete(p.statsCollectors.cancels, key)
+	p.statsCollectors.mu.Unlock()
+
+	if exists {
+		cancel()
+	}
+}
+
+var (
+	gaugeInstanceCPUPercent = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "pwd",
+		Subsystem: "instance",
+		Name:      "cpu_percent",
+		Help:      "CPU usage percent of a running instance.",
+	}, []string{"session", "instance"})
+	gaugeInstanceMemBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "pwd",
+		Subsystem: "instance",
+		Name:      "mem_bytes",
+		Help:      "Memory usage in bytes of a running instance.",
+	}, []string{"session", "instance"})
+	gaugeInstanceNetRxBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "pwd",
+		Subsystem: "instance",
+		Name:      "net_rx_bytes",
+		Help:      "Total bytes received over the network by a running instance.",
+	}, []string{"session", "instance"})
+	gaugeInstanceNetTxBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "pwd",
+		Subsystem: "instance",
+		Name:      "net_tx_bytes",
+		Help:      "Total bytes sent over the network by a running instance.",
+	}, []string{"session", "instance"})
+)
+
+func init() {
+	prometheus.MustRegister(gaugeInstanceCPUPercent, gaugeInstanceMemBytes, gaugeInstanceNetRxBytes, gaugeInstanceNetTxBytes)
+}
+
+// InstanceStats streams resource usage samples for instance until ctx is
+// cancelled or the instance stops. The returned channel is closed when
+// streaming ends.
+func (p *pwd) InstanceStats(ctx context.Context, instance *types.Instance) (<-chan types.InstanceStats, error) {
+	defer observeAction("InstanceStats", time.Now())
+
+	raw, err := p.docker.ContainerStats(ctx, instance.Name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan types.InstanceStats)
+	go func() {
+		defer close(out)
+		defer raw.Close()
+		defer p.clearStatsGauges(instance)
+
+		dec := json.NewDecoder(raw)
+		for {
+			var sample dockerTypes.StatsJSON
+			if err := dec.Decode(&sample); err != nil {
+				if err != io.EOF {
+					log.Println("error decoding stats for", instance.Name, err)
+				}
+				return
+			}
+
+			stats := instanceStatsFromDocker(instance, &sample)
+			p.setStatsGauges(instance, stats)
+			p.event.Emit(event.INSTANCE_STATS, instance.SessionId, instance.Name, stats)
+
+			select {
+			case out <- stats:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// InstanceStatsOne returns a single resource usage snapshot for instance.
+func (p *pwd) InstanceStatsOne(instance *types.Instance) (types.InstanceStats, error) {
+	defer observeAction("InstanceStatsOne", time.Now())
+	p.idle.touch(instance.SessionId, instance.Name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	raw, err := p.docker.ContainerStats(ctx, instance.Name, false)
+	if err != nil {
+		return types.InstanceStats{}, err
+	}
+	defer raw.Close()
+
+	var sample dockerTypes.StatsJSON
+	if err := json.NewDecoder(raw).Decode(&sample); err != nil {
+		return types.InstanceStats{}, err
+	}
+
+	return instanceStatsFromDocker(instance, &sample), nil
+}
+
+// instanceStatsFromDocker converts a single Docker stats sample into an
+// InstanceStats, computing CPU percent the same way `docker stats` does: the
+// CPU delta over the system delta, scaled by the number of online CPUs.
+func instanceStatsFromDocker(instance *types.Instance, s *dockerTypes.StatsJSON) types.InstanceStats {
+	var cpuPercent float64
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemUsage) - float64(s.PreCPUStats.SystemUsage)
+	if cpuDelta > 0 && systemDelta > 0 {
+		onlineCPUs := float64(s.CPUStats.OnlineCPUs)
+		if onlineCPUs == 0 {
+			onlineCPUs = float64(len(s.CPUStats.CPUUsage.PercpuUsage))
+		}
+		cpuPercent = (cpuDelta / systemDelta) * onlineCPUs * 100.0
+	}
+
+	var rx, tx uint64
+	for _, n := range s.Networks {
+		rx += n.RxBytes
+		tx += n.TxBytes
+	}
+
+	var blkRead, blkWrite uint64
+	for _, e := range s.BlkioStats.IoServiceBytesRecursive {
+		switch e.Op {
+		case "Read":
+			blkRead += e.Value
+		case "Write":
+			blkWrite += e.Value
+		}
+	}
+
+	var memPercent float64
+	if s.MemoryStats.Limit > 0 {
+		memPercent = float64(s.MemoryStats.Usage) / float64(s.MemoryStats.Limit) * 100.0
+	}
+
+	return types.InstanceStats{
+		SessionId:    instance.SessionId,
+		InstanceName: instance.Name,
+		Read:         s.Read,
+		CPUPercent:   cpuPercent,
+		MemUsage:     s.MemoryStats.Usage,
+		MemLimit:     s.MemoryStats.Limit,
+		MemPercent:   memPercent,
+		NetworkRx:    rx,
+		NetworkTx:    tx,
+		BlockRead:    blkRead,
+		BlockWrite:   blkWrite,
+	}
+}
+
+func (p *pwd) setStatsGauges(instance *types.Instance, stats types.InstanceStats) {
+	labels := prometheus.Labels{"session": instance.SessionId, "instance": instance.Name}
+	gaugeInstanceCPUPercent.With(labels).Set(stats.CPUPercent)
+	gaugeInstanceMemBytes.With(labels).Set(float64(stats.MemUsage))
+	gaugeInstanceNetRxBytes.With(labels).Set(float64(stats.NetworkRx))
+	gaugeInstanceNetTxBytes.With(labels).Set(float64(stats.NetworkTx))
+}
+
+func (p *pwd) clearStatsGauges(instance *types.Instance) {
+	labels := prometheus.Labels{"session": instance.SessionId, "instance": instance.Name}
+	gaugeInstanceCPUPercent.Delete(labels)
+	gaugeInstanceMemBytes.Delete(labels)
+	gaugeInstanceNetRxBytes.Delete(labels)
+	gaugeInstanceNetTxBytes.Delete(labels)
+}
@@ -0,0 +1,107 @@
+package pwd
+
+import (
+	"testing"
+
+	dockerTypes "github.com/docker/docker/api/types"
+	"github.com/play-with-docker/play-with-docker/pwd/types"
+)
+
+func TestInstanceStatsFromDockerComputesCPUPercent(t *testing.T) {
+	instance := &types.Instance{SessionId: "abc123", Name: "node1"}
+
+	sample := &dockerTypes.StatsJSON{}
+	sample.CPUStats.CPUUsage.TotalUsage = 300
+	sample.PreCPUStats.CPUUsage.TotalUsage = 100
+	sample.CPUStats.SystemUsage = 1000
+	sample.PreCPUStats.SystemUsage = 0
+	sample.CPUStats.OnlineCPUs = 2
+
+	stats := instanceStatsFromDocker(instance, sample)
+
+	// (300-100)/(1000-0) * 2 * 100 = 40
+	if got, want := stats.CPUPercent, 40.0; got != want {
+		t.Errorf("CPUPercent = %v, want %v", got, want)
+	}
+	if stats.SessionId != instance.SessionId || stats.InstanceName != instance.Name {
+		t.Errorf("stats did not carry over session/instance identity: %+v", stats)
+	}
+}
+
+func TestInstanceStatsFromDockerNoSystemDeltaIsZeroPercent(t *testing.T) {
+	instance := &types.Instance{SessionId: "abc123", Name: "node1"}
+
+	sample := &dockerTypes.StatsJSON{}
+	sample.CPUStats.CPUUsage.TotalUsage = 300
+	sample.PreCPUStats.CPUUsage.TotalUsage = 100
+	sample.CPUStats.SystemUsage = 500
+	sample.PreCPUStats.SystemUsage = 500
+
+	stats := instanceStatsFromDocker(instance, sample)
+
+	if stats.CPUPercent != 0 {
+		t.Errorf("CPUPercent = %v, want 0 when there is no system delta", stats.CPUPercent)
+	}
+}
+
+func TestInstanceStatsFromDockerMemoryPercent(t *testing.T) {
+	instance := &types.Instance{}
+
+	sample := &dockerTypes.StatsJSON{}
+	sample.MemoryStats.Usage = 50
+	sample.MemoryStats.Limit = 200
+
+	stats := instanceStatsFromDocker(instance, sample)
+
+	if got, want := stats.MemUsage, uint64(50); got != want {
+		t.Errorf("MemUsage = %v, want %v", got, want)
+	}
+	if got, want := stats.MemPercent, 25.0; got != want {
+		t.Errorf("MemPercent = %v, want %v", got, want)
+	}
+}
+
+func TestInstanceStatsFromDockerNoMemoryLimitIsZeroPercent(t *testing.T) {
+	instance := &types.Instance{}
+
+	sample := &dockerTypes.StatsJSON{}
+	sample.MemoryStats.Usage = 50
+	sample.MemoryStats.Limit = 0
+
+	stats := instanceStatsFromDocker(instance, sample)
+
+	if stats.MemPercent != 0 {
+		t.Errorf("MemPercent = %v, want 0 when there is no memory limit", stats.MemPercent)
+	}
+}
+
+func TestInstanceStatsFromDockerSumsNetworksAndBlockIO(t *testing.T) {
+	instance := &types.Instance{}
+
+	sample := &dockerTypes.StatsJSON{}
+	sample.Networks = map[string]dockerTypes.NetworkStats{
+		"eth0": {RxBytes: 10, TxBytes: 20},
+		"eth1": {RxBytes: 5, TxBytes: 7},
+	}
+	sample.BlkioStats.IoServiceBytesRecursive = []dockerTypes.BlkioStatEntry{
+		{Op: "Read", Value: 100},
+		{Op: "Write", Value: 40},
+		{Op: "Read", Value: 8},
+		{Op: "Sync", Value: 1000}, // ignored: not a read/write total
+	}
+
+	stats := instanceStatsFromDocker(instance, sample)
+
+	if got, want := stats.NetworkRx, uint64(15); got != want {
+		t.Errorf("NetworkRx = %v, want %v", got, want)
+	}
+	if got, want := stats.NetworkTx, uint64(27); got != want {
+		t.Errorf("NetworkTx = %v, want %v", got, want)
+	}
+	if got, want := stats.BlockRead, uint64(108); got != want {
+		t.Errorf("BlockRead = %v, want %v", got, want)
+	}
+	if got, want := stats.BlockWrite, uint64(40); got != want {
+		t.Errorf("BlockWrite = %v, want %v", got, want)
+	}
+}
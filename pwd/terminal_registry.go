@@ -0,0 +1,76 @@
+package pwd
+
+import (
+	"net"
+	"sync"
+)
+
+// terminalRegistry tracks the live terminal connection for every instance of
+// every session. It replaces the old package-level terms map so connections
+// are safe to read and write concurrently, and so a session's connections can
+// be closed and released in one call (DeleteSession). It also means multiple
+// pwd instances, each with their own registry, can run side by side without
+// sharing global state, which is what lets tests spin up an isolated pwd.
+type terminalRegistry struct {
+	sync.RWMutex
+	conns map[string]map[string]net.Conn
+}
+
+func newTerminalRegistry() *terminalRegistry {
+	return &terminalRegistry{conns: make(map[string]map[string]net.Conn)}
+}
+
+// Get returns the connection for instanceName in sessionId, or nil if there is none.
+func (r *terminalRegistry) Get(sessionId, instanceName string) net.Conn {
+	r.RLock()
+	defer r.RUnlock()
+	return r.conns[sessionId][instanceName]
+}
+
+// Set registers conn as the terminal connection for instanceName in sessionId.
+func (r *terminalRegistry) Set(sessionId, instanceName string, conn net.Conn) {
+	r.Lock()
+	defer r.Unlock()
+	if r.conns[sessionId] == nil {
+		r.conns[sessionId] = make(map[string]net.Conn)
+	}
+	r.conns[sessionId][instanceName] = conn
+}
+
+// Delete closes and forgets the connection for instanceName in sessionId, if any.
+func (r *terminalRegistry) Delete(sessionId, instanceName string) {
+	r.Lock()
+	defer r.Unlock()
+	conns := r.conns[sessionId]
+	if conns == nil {
+		return
+	}
+	if conn, ok := conns[instanceName]; ok {
+		conn.Close()
+		delete(conns, instanceName)
+	}
+}
+
+// DeleteSession closes every connection belonging to sessionId and removes it
+// from the registry. Wired into SessionClose so a torn down session doesn't
+// leak its instances' connections.
+func (r *terminalRegistry) DeleteSession(sessionId string) {
+	r.Lock()
+	defer r.Unlock()
+	for _, conn := range r.conns[sessionId] {
+		conn.Close()
+	}
+	delete(r.conns, sessionId)
+}
+
+// CloseAll closes every connection tracked by the registry, across all sessions.
+func (r *terminalRegistry) CloseAll() {
+	r.Lock()
+	defer r.Unlock()
+	for sessionId, conns := range r.conns {
+		for _, conn := range conns {
+			conn.Close()
+		}
+		delete(r.conns, sessionId)
+	}
+}
@@ -0,0 +1,23 @@
+package types
+
+import "time"
+
+// InstanceStats is a single resource-usage sample for a running instance, as
+// produced by InstanceStats/InstanceStatsOne.
+type InstanceStats struct {
+	SessionId    string
+	InstanceName string
+	Read         time.Time
+
+	CPUPercent float64
+
+	MemUsage   uint64
+	MemLimit   uint64
+	MemPercent float64
+
+	NetworkRx uint64
+	NetworkTx uint64
+
+	BlockRead  uint64
+	BlockWrite uint64
+}
@@ -0,0 +1,34 @@
+package types
+
+import (
+	"sync"
+	"time"
+)
+
+// Instance represents a single running container handed out to a session.
+type Instance struct {
+	Image        string
+	IP           string
+	SessionId    string
+	Name         string
+	Hostname     string
+	Alias        string
+	Cert         []byte
+	Key          []byte
+	ServerCert   []byte
+	ServerKey    []byte
+	CACert       []byte
+	IsDockerHost bool
+	CreatedAt    time.Time
+
+	Session *Session
+}
+
+// Session groups the instances a single user's lab is made of.
+type Session struct {
+	sync.Mutex
+
+	Id           string
+	PwdIpAddress string
+	Instances    map[string]*Instance
+}
@@ -0,0 +1,124 @@
+package pwd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// UserEntry is a single line to add to a container's generated /etc/passwd.
+type UserEntry struct {
+	Name  string
+	Uid   int
+	Gid   int
+	Home  string
+	Shell string
+}
+
+// GroupEntry is a single line to add to a container's generated /etc/group.
+type GroupEntry struct {
+	Name string
+	Gid  int
+}
+
+func (u UserEntry) passwdLine() string {
+	home := u.Home
+	if home == "" {
+		home = "/home/" + u.Name
+	}
+	shell := u.Shell
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return fmt.Sprintf("%s:x:%d:%d::%s:%s\n", u.Name, u.Uid, u.Gid, home, shell)
+}
+
+func (g GroupEntry) groupLine() string {
+	return fmt.Sprintf("%s:x:%d:\n", g.Name, g.Gid)
+}
+
+// baseUsers and baseGroups are the accounts every base image ships with that
+// system tooling (su, sudo, sshd, cron, even bash's prompt) does NSS lookups
+// against. writePasswdAndGroup's output replaces /etc/passwd and /etc/group
+// wholesale via a bind mount, so these have to be emitted alongside
+// ExtraUsers/ExtraGroups or they'd vanish from the container entirely.
+var baseUsers = []UserEntry{
+	{Name: "root", Uid: 0, Gid: 0, Home: "/root", Shell: "/bin/sh"},
+	{Name: "daemon", Uid: 1, Gid: 1, Home: "/usr/sbin", Shell: "/usr/sbin/nologin"},
+	{Name: "bin", Uid: 2, Gid: 2, Home: "/bin", Shell: "/usr/sbin/nologin"},
+	{Name: "sys", Uid: 3, Gid: 3, Home: "/dev", Shell: "/usr/sbin/nologin"},
+	{Name: "nobody", Uid: 65534, Gid: 65534, Home: "/nonexistent", Shell: "/usr/sbin/nologin"},
+}
+
+var baseGroups = []GroupEntry{
+	{Name: "root", Gid: 0},
+	{Name: "daemon", Gid: 1},
+	{Name: "bin", Gid: 2},
+	{Name: "sys", Gid: 3},
+	{Name: "nogroup", Gid: 65534},
+}
+
+// userFilesDir is the per-session tmpfs directory that holds generated
+// passwd/group files, created on first use.
+func userFilesDir(sessionId string) (string, error) {
+	dir := filepath.Join(os.TempDir(), "pwd-users", sessionId)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// writePasswdAndGroup renders users and groups into passwd/group files under
+// the session's tmpfs directory and returns their host paths, ready to be
+// bind mounted read-only into a container as /etc/passwd and /etc/group.
+func writePasswdAndGroup(sessionId, instanceName string, users []UserEntry, groups []GroupEntry) (passwdPath, groupPath string, err error) {
+	dir, err := userFilesDir(sessionId)
+	if err != nil {
+		return "", "", err
+	}
+
+	var passwd, group []byte
+	for _, u := range baseUsers {
+		passwd = append(passwd, []byte(u.passwdLine())...)
+	}
+	for _, u := range users {
+		passwd = append(passwd, []byte(u.passwdLine())...)
+	}
+	for _, g := range baseGroups {
+		group = append(group, []byte(g.groupLine())...)
+	}
+	for _, g := range groups {
+		group = append(group, []byte(g.groupLine())...)
+	}
+
+	passwdPath = filepath.Join(dir, instanceName+".passwd")
+	groupPath = filepath.Join(dir, instanceName+".group")
+
+	if err := ioutil.WriteFile(passwdPath, passwd, 0644); err != nil {
+		return "", "", err
+	}
+	if err := ioutil.WriteFile(groupPath, group, 0644); err != nil {
+		os.Remove(passwdPath)
+		return "", "", err
+	}
+
+	return passwdPath, groupPath, nil
+}
+
+// removeUserFiles deletes the generated passwd/group files for an instance.
+// Called from InstanceDelete; harmless if the instance never had any.
+func removeUserFiles(sessionId, instanceName string) {
+	dir, err := userFilesDir(sessionId)
+	if err != nil {
+		return
+	}
+	os.Remove(filepath.Join(dir, instanceName+".passwd"))
+	os.Remove(filepath.Join(dir, instanceName+".group"))
+}
+
+// removeSessionUserFiles deletes the whole per-session tmpfs directory used
+// for generated passwd/group files. Called from SessionClose.
+func removeSessionUserFiles(sessionId string) {
+	os.RemoveAll(filepath.Join(os.TempDir(), "pwd-users", sessionId))
+}
@@ -0,0 +1,69 @@
+package pwd
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestUserEntryPasswdLine(t *testing.T) {
+	u := UserEntry{Name: "alice", Uid: 1001, Gid: 1001, Home: "/home/alice", Shell: "/bin/bash"}
+
+	got := u.passwdLine()
+	want := "alice:x:1001:1001::/home/alice:/bin/bash\n"
+	if got != want {
+		t.Errorf("passwdLine() = %q, want %q", got, want)
+	}
+}
+
+func TestUserEntryPasswdLineDefaults(t *testing.T) {
+	u := UserEntry{Name: "bob", Uid: 1002, Gid: 1002}
+
+	got := u.passwdLine()
+	want := "bob:x:1002:1002::/home/bob:/bin/sh\n"
+	if got != want {
+		t.Errorf("passwdLine() = %q, want %q", got, want)
+	}
+}
+
+func TestGroupEntryGroupLine(t *testing.T) {
+	g := GroupEntry{Name: "devs", Gid: 2000}
+
+	got := g.groupLine()
+	want := "devs:x:2000:\n"
+	if got != want {
+		t.Errorf("groupLine() = %q, want %q", got, want)
+	}
+}
+
+func TestWritePasswdAndGroupKeepsBaseAccounts(t *testing.T) {
+	passwdPath, groupPath, err := writePasswdAndGroup("session123", "session1_node1",
+		[]UserEntry{{Name: "alice", Uid: 1001, Gid: 1001}},
+		[]GroupEntry{{Name: "devs", Gid: 2000}})
+	if err != nil {
+		t.Fatalf("writePasswdAndGroup() error = %v", err)
+	}
+	defer removeSessionUserFiles("session123")
+
+	passwd, err := ioutil.ReadFile(passwdPath)
+	if err != nil {
+		t.Fatalf("could not read generated passwd file: %v", err)
+	}
+	if !strings.Contains(string(passwd), "root:x:0:0::/root:/bin/sh\n") {
+		t.Errorf("generated passwd file is missing the root entry:\n%s", passwd)
+	}
+	if !strings.Contains(string(passwd), "alice:x:1001:1001::/home/alice:/bin/sh\n") {
+		t.Errorf("generated passwd file is missing the extra user entry:\n%s", passwd)
+	}
+
+	group, err := ioutil.ReadFile(groupPath)
+	if err != nil {
+		t.Fatalf("could not read generated group file: %v", err)
+	}
+	if !strings.Contains(string(group), "root:x:0:\n") {
+		t.Errorf("generated group file is missing the root group:\n%s", group)
+	}
+	if !strings.Contains(string(group), "devs:x:2000:\n") {
+		t.Errorf("generated group file is missing the extra group entry:\n%s", group)
+	}
+}
@@ -0,0 +1,20 @@
+package storage
+
+import "github.com/play-with-docker/play-with-docker/pwd/types"
+
+// StorageApi persists sessions and instances, and lets them be looked back up
+// by the various keys the frontend and Docker event bridge need.
+type StorageApi interface {
+	InstanceFindByIP(ip string) (*types.Instance, error)
+	InstanceFindByIPAndSession(sessionPrefix, ip string) (*types.Instance, error)
+	InstanceFindByAlias(sessionPrefix, alias string) (*types.Instance, error)
+	// InstanceFindByName looks up an instance by its full container name,
+	// e.g. to map a Docker event back to the PWD instance it belongs to.
+	InstanceFindByName(name string) (*types.Instance, error)
+	InstanceCreate(sessionId string, instance *types.Instance) error
+	InstanceDelete(sessionId, instanceName string) error
+	// InstanceSetState records a free-form lifecycle state for an instance,
+	// e.g. "died", so it can be reflected back to the frontend.
+	InstanceSetState(sessionId, instanceName, state string) error
+	SessionGetAll() []*types.Session
+}